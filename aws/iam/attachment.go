@@ -0,0 +1,262 @@
+package iam
+
+import (
+	"fmt"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+
+	"github.com/redradrat/cloud-objects/aws"
+)
+
+// AttachmentTargetType identifies the kind of principal a policy is attached to.
+type AttachmentTargetType string
+
+const (
+	RoleAttachmentTarget  AttachmentTargetType = "Role"
+	UserAttachmentTarget  AttachmentTargetType = "User"
+	GroupAttachmentTarget AttachmentTargetType = "Group"
+)
+
+func attachRolePolicy(svc iamiface.IAMAPI, roleName string, policyArn awsarn.ARN) error {
+	_, err := svc.AttachRolePolicy(&iam.AttachRolePolicyInput{
+		RoleName:  awssdk.String(roleName),
+		PolicyArn: awssdk.String(policyArn.String()),
+	})
+	return err
+}
+
+func detachRolePolicy(svc iamiface.IAMAPI, roleName string, policyArn awsarn.ARN) error {
+	_, err := svc.DetachRolePolicy(&iam.DetachRolePolicyInput{
+		RoleName:  awssdk.String(roleName),
+		PolicyArn: awssdk.String(policyArn.String()),
+	})
+	return err
+}
+
+func attachUserPolicy(svc iamiface.IAMAPI, userName string, policyArn awsarn.ARN) error {
+	_, err := svc.AttachUserPolicy(&iam.AttachUserPolicyInput{
+		UserName:  awssdk.String(userName),
+		PolicyArn: awssdk.String(policyArn.String()),
+	})
+	return err
+}
+
+func detachUserPolicy(svc iamiface.IAMAPI, userName string, policyArn awsarn.ARN) error {
+	_, err := svc.DetachUserPolicy(&iam.DetachUserPolicyInput{
+		UserName:  awssdk.String(userName),
+		PolicyArn: awssdk.String(policyArn.String()),
+	})
+	return err
+}
+
+func attachGroupPolicy(svc iamiface.IAMAPI, groupName string, policyArn awsarn.ARN) error {
+	_, err := svc.AttachGroupPolicy(&iam.AttachGroupPolicyInput{
+		GroupName: awssdk.String(groupName),
+		PolicyArn: awssdk.String(policyArn.String()),
+	})
+	return err
+}
+
+func detachGroupPolicy(svc iamiface.IAMAPI, groupName string, policyArn awsarn.ARN) error {
+	_, err := svc.DetachGroupPolicy(&iam.DetachGroupPolicyInput{
+		GroupName: awssdk.String(groupName),
+		PolicyArn: awssdk.String(policyArn.String()),
+	})
+	return err
+}
+
+// listAttachedRolePolicies returns the ARNs of all managed policies currently attached to the given role.
+func listAttachedRolePolicies(svc iamiface.IAMAPI, roleName string) ([]awsarn.ARN, error) {
+	var arns []awsarn.ARN
+	out, err := svc.ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{
+		RoleName: awssdk.String(roleName),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range out.AttachedPolicies {
+		parsed, err := awsarn.Parse(awssdk.StringValue(p.PolicyArn))
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, parsed)
+	}
+	return arns, nil
+}
+
+func listAttachedUserPolicies(svc iamiface.IAMAPI, userName string) ([]awsarn.ARN, error) {
+	var arns []awsarn.ARN
+	out, err := svc.ListAttachedUserPolicies(&iam.ListAttachedUserPoliciesInput{
+		UserName: awssdk.String(userName),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range out.AttachedPolicies {
+		parsed, err := awsarn.Parse(awssdk.StringValue(p.PolicyArn))
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, parsed)
+	}
+	return arns, nil
+}
+
+func listAttachedGroupPolicies(svc iamiface.IAMAPI, groupName string) ([]awsarn.ARN, error) {
+	var arns []awsarn.ARN
+	out, err := svc.ListAttachedGroupPolicies(&iam.ListAttachedGroupPoliciesInput{
+		GroupName: awssdk.String(groupName),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range out.AttachedPolicies {
+		parsed, err := awsarn.Parse(awssdk.StringValue(p.PolicyArn))
+		if err != nil {
+			return nil, err
+		}
+		arns = append(arns, parsed)
+	}
+	return arns, nil
+}
+
+// PolicyAttachmentInstance represents the attachment of a single managed policy to a single
+// principal (Role, User or Group). Unlike PolicyInstance and RoleInstance, there is nothing to
+// create or update beyond the attachment itself; Create/Delete simply attach/detach the policy.
+type PolicyAttachmentInstance struct {
+	PolicyArn  awsarn.ARN
+	TargetType AttachmentTargetType
+	TargetName string
+	attached   bool
+}
+
+func NewPolicyAttachmentInstance(policyArn awsarn.ARN, targetType AttachmentTargetType, targetName string) *PolicyAttachmentInstance {
+	return &PolicyAttachmentInstance{
+		PolicyArn:  policyArn,
+		TargetType: targetType,
+		TargetName: targetName,
+	}
+}
+
+// Create attaches the referenced policy to the referenced target.
+func (a *PolicyAttachmentInstance) Create(svc iamiface.IAMAPI) error {
+	var err error
+	switch a.TargetType {
+	case RoleAttachmentTarget:
+		err = attachRolePolicy(svc, a.TargetName, a.PolicyArn)
+	case UserAttachmentTarget:
+		err = attachUserPolicy(svc, a.TargetName, a.PolicyArn)
+	case GroupAttachmentTarget:
+		err = attachGroupPolicy(svc, a.TargetName, a.PolicyArn)
+	default:
+		return fmt.Errorf("unknown attachment target type '%s'", a.TargetType)
+	}
+	if err != nil {
+		return err
+	}
+	a.attached = true
+	return nil
+}
+
+// Read checks whether the referenced policy is still attached to the referenced target.
+func (a *PolicyAttachmentInstance) Read(svc iamiface.IAMAPI) error {
+	var attached []awsarn.ARN
+	var err error
+	switch a.TargetType {
+	case RoleAttachmentTarget:
+		attached, err = listAttachedRolePolicies(svc, a.TargetName)
+	case UserAttachmentTarget:
+		attached, err = listAttachedUserPolicies(svc, a.TargetName)
+	case GroupAttachmentTarget:
+		attached, err = listAttachedGroupPolicies(svc, a.TargetName)
+	default:
+		return fmt.Errorf("unknown attachment target type '%s'", a.TargetType)
+	}
+	if err != nil {
+		return err
+	}
+
+	a.attached = false
+	for _, arn := range attached {
+		if arn.String() == a.PolicyArn.String() {
+			a.attached = true
+			break
+		}
+	}
+	return nil
+}
+
+// Delete detaches the referenced policy from the referenced target.
+func (a *PolicyAttachmentInstance) Delete(svc iamiface.IAMAPI) error {
+	var err error
+	switch a.TargetType {
+	case RoleAttachmentTarget:
+		err = detachRolePolicy(svc, a.TargetName, a.PolicyArn)
+	case UserAttachmentTarget:
+		err = detachUserPolicy(svc, a.TargetName, a.PolicyArn)
+	case GroupAttachmentTarget:
+		err = detachGroupPolicy(svc, a.TargetName, a.PolicyArn)
+	default:
+		return fmt.Errorf("unknown attachment target type '%s'", a.TargetType)
+	}
+	if err != nil {
+		return err
+	}
+	a.attached = false
+	return nil
+}
+
+func (a *PolicyAttachmentInstance) IsAttached() bool {
+	return a.attached
+}
+
+// AttachToRole attaches this policy to the given role.
+func (p *PolicyInstance) AttachToRole(svc iamiface.IAMAPI, roleArn awsarn.ARN) error {
+	if !p.IsCreated(svc) {
+		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
+	}
+	return NewPolicyAttachmentInstance(p.arn, RoleAttachmentTarget, FriendlyNamefromARN(roleArn)).Create(svc)
+}
+
+// DetachFromRole detaches this policy from the given role.
+func (p *PolicyInstance) DetachFromRole(svc iamiface.IAMAPI, roleArn awsarn.ARN) error {
+	if !p.IsCreated(svc) {
+		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
+	}
+	return NewPolicyAttachmentInstance(p.arn, RoleAttachmentTarget, FriendlyNamefromARN(roleArn)).Delete(svc)
+}
+
+// AttachToUser attaches this policy to the given user.
+func (p *PolicyInstance) AttachToUser(svc iamiface.IAMAPI, userArn awsarn.ARN) error {
+	if !p.IsCreated(svc) {
+		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
+	}
+	return NewPolicyAttachmentInstance(p.arn, UserAttachmentTarget, FriendlyNamefromARN(userArn)).Create(svc)
+}
+
+// DetachFromUser detaches this policy from the given user.
+func (p *PolicyInstance) DetachFromUser(svc iamiface.IAMAPI, userArn awsarn.ARN) error {
+	if !p.IsCreated(svc) {
+		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
+	}
+	return NewPolicyAttachmentInstance(p.arn, UserAttachmentTarget, FriendlyNamefromARN(userArn)).Delete(svc)
+}
+
+// AttachToGroup attaches this policy to the given group.
+func (p *PolicyInstance) AttachToGroup(svc iamiface.IAMAPI, groupArn awsarn.ARN) error {
+	if !p.IsCreated(svc) {
+		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
+	}
+	return NewPolicyAttachmentInstance(p.arn, GroupAttachmentTarget, FriendlyNamefromARN(groupArn)).Create(svc)
+}
+
+// DetachFromGroup detaches this policy from the given group.
+func (p *PolicyInstance) DetachFromGroup(svc iamiface.IAMAPI, groupArn awsarn.ARN) error {
+	if !p.IsCreated(svc) {
+		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
+	}
+	return NewPolicyAttachmentInstance(p.arn, GroupAttachmentTarget, FriendlyNamefromARN(groupArn)).Delete(svc)
+}
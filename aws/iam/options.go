@@ -0,0 +1,102 @@
+package iam
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+// namePrefixSuffix returns a short random hex suffix, matching the name_prefix convention used
+// throughout the Terraform AWS provider for generating unique resource names.
+func namePrefixSuffix() string {
+	b := make([]byte, 4)
+	// crypto/rand.Read on the standard reader never returns an error in practice; a zero suffix
+	// is an acceptable degradation rather than a reason to plumb an error through every option.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func toIAMTags(tags map[string]string) []*iam.Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+	out := make([]*iam.Tag, 0, len(tags))
+	for k, v := range tags {
+		out = append(out, &iam.Tag{Key: awssdk.String(k), Value: awssdk.String(v)})
+	}
+	return out
+}
+
+func fromIAMTags(tags []*iam.Tag) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(tags))
+	for _, t := range tags {
+		out[awssdk.StringValue(t.Key)] = awssdk.StringValue(t.Value)
+	}
+	return out
+}
+
+// diffTags returns the keys to remove and the key/value pairs to add/update in order to bring
+// current in line with desired.
+func diffTags(current, desired map[string]string) (put map[string]string, remove []string) {
+	put = make(map[string]string)
+	for k, v := range desired {
+		if cur, ok := current[k]; !ok || cur != v {
+			put[k] = v
+		}
+	}
+	for k := range current {
+		if _, ok := desired[k]; !ok {
+			remove = append(remove, k)
+		}
+	}
+	return
+}
+
+// PolicyInstanceOption configures optional fields on a PolicyInstance at construction time.
+type PolicyInstanceOption func(*PolicyInstance)
+
+// WithPolicyPath sets the IAM path under which the policy is created.
+func WithPolicyPath(path string) PolicyInstanceOption {
+	return func(p *PolicyInstance) { p.Path = path }
+}
+
+// WithPolicyTags sets the tags applied to the policy.
+func WithPolicyTags(tags map[string]string) PolicyInstanceOption {
+	return func(p *PolicyInstance) { p.Tags = tags }
+}
+
+// WithPolicyNamePrefix appends a unique suffix to prefix and uses the result as the policy name,
+// useful for controllers that create ephemeral policies.
+func WithPolicyNamePrefix(prefix string) PolicyInstanceOption {
+	return func(p *PolicyInstance) { p.Name = prefix + namePrefixSuffix() }
+}
+
+// RoleInstanceOption configures optional fields on a RoleInstance at construction time.
+type RoleInstanceOption func(*RoleInstance)
+
+// WithRolePath sets the IAM path under which the role is created.
+func WithRolePath(path string) RoleInstanceOption {
+	return func(r *RoleInstance) { r.Path = path }
+}
+
+// WithPermissionsBoundary sets the managed policy used as the role's permissions boundary.
+func WithPermissionsBoundary(arn awsarn.ARN) RoleInstanceOption {
+	return func(r *RoleInstance) { r.PermissionsBoundary = arn }
+}
+
+// WithRoleTags sets the tags applied to the role.
+func WithRoleTags(tags map[string]string) RoleInstanceOption {
+	return func(r *RoleInstance) { r.Tags = tags }
+}
+
+// WithRoleNamePrefix appends a unique suffix to prefix and uses the result as the role name,
+// useful for controllers that create ephemeral roles.
+func WithRoleNamePrefix(prefix string) RoleInstanceOption {
+	return func(r *RoleInstance) { r.Name = prefix + namePrefixSuffix() }
+}
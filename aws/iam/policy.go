@@ -3,6 +3,8 @@ package iam
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"reflect"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
@@ -13,17 +15,23 @@ import (
 	"github.com/redradrat/cloud-objects/aws"
 )
 
-func createPolicy(svc iamiface.IAMAPI, polName, polDesc string, pd PolicyDocument) (*iam.CreatePolicyOutput, error) {
+func createPolicy(svc iamiface.IAMAPI, polName, polDesc, path string, pd PolicyDocument, tags map[string]string) (*iam.CreatePolicyOutput, error) {
 	b, err := json.Marshal(&pd)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := svc.CreatePolicy(&iam.CreatePolicyInput{
+	input := &iam.CreatePolicyInput{
 		PolicyDocument: awssdk.String(string(b)),
 		Description:    awssdk.String(polDesc),
 		PolicyName:     awssdk.String(polName),
-	})
+		Tags:           toIAMTags(tags),
+	}
+	if path != "" {
+		input.Path = awssdk.String(path)
+	}
+
+	result, err := svc.CreatePolicy(input)
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,28 @@ func createPolicy(svc iamiface.IAMAPI, polName, polDesc string, pd PolicyDocumen
 	return result, nil
 }
 
+func tagPolicy(svc iamiface.IAMAPI, arn awsarn.ARN, tags map[string]string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := svc.TagPolicy(&iam.TagPolicyInput{
+		PolicyArn: awssdk.String(arn.String()),
+		Tags:      toIAMTags(tags),
+	})
+	return err
+}
+
+func untagPolicy(svc iamiface.IAMAPI, arn awsarn.ARN, tagKeys []string) error {
+	if len(tagKeys) == 0 {
+		return nil
+	}
+	_, err := svc.UntagPolicy(&iam.UntagPolicyInput{
+		PolicyArn: awssdk.String(arn.String()),
+		TagKeys:   awssdk.StringSlice(tagKeys),
+	})
+	return err
+}
+
 func updatePolicy(svc iamiface.IAMAPI, policyArn awsarn.ARN, pd PolicyDocument) (*iam.CreatePolicyVersionOutput, error) {
 	b, err := json.Marshal(&pd)
 	if err != nil {
@@ -117,52 +147,43 @@ type PolicyInstance struct {
 	Description    string
 	PolicyDocument PolicyDocument
 	arn            awsarn.ARN
+
+	// Path is the IAM path the policy is created under. Defaults to "/" when empty.
+	Path string
+	// Tags are the IAM tags applied to the policy.
+	Tags map[string]string
+
+	// defaultVersionId is the policy's DefaultVersionId as observed at the last Read or Update. Update uses
+	// it as an optimistic-concurrency token: if the live default version no longer matches, the
+	// policy was edited out-of-band and Update refuses to proceed.
+	defaultVersionId string
 }
 
-func NewPolicyInstance(name, description string, policyDoc PolicyDocument) *PolicyInstance {
-	return &PolicyInstance{Name: name, Description: description, PolicyDocument: policyDoc}
+func NewPolicyInstance(name, description string, policyDoc PolicyDocument, opts ...PolicyInstanceOption) *PolicyInstance {
+	p := &PolicyInstance{Name: name, Description: description, PolicyDocument: policyDoc}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
-func NewExistingPolicyInstance(name, description string, policyDoc PolicyDocument, arn awsarn.ARN) *PolicyInstance {
-	return &PolicyInstance{
+func NewExistingPolicyInstance(name, description string, policyDoc PolicyDocument, arn awsarn.ARN, opts ...PolicyInstanceOption) *PolicyInstance {
+	p := &PolicyInstance{
 		Name:           name,
 		Description:    description,
 		PolicyDocument: policyDoc,
 		arn:            arn,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
-// Abandoned fetch implementation
-//func NewExistingPolicyInstance(svc iamiface.IAMAPI, arn awsarn.ARN) (*PolicyInstance, error) {
-//	var pi *PolicyInstance
-//	emptyarn := awsarn.ARN{}.String()
-//	if arn.String() == emptyarn {
-//		return pi, fmt.Errorf("given ARN is empty")
-//	}
-//
-//	out, err := getPolicy(svc, arn)
-//	if err != nil {
-//		return pi, err
-//	}
-//
-//	pdout, err := getPolicyVersion(svc, *out)
-//	if err != nil {
-//		return pi, err
-//	}
-//	var pd PolicyDocument
-//	json.Unmarshal([]byte(awssdk.StringValue(pdout.PolicyVersion.Document)), &pd)
-//	pi = &PolicyInstance{
-//		Name:           awssdk.StringValue(out.Policy.PolicyName),
-//		Description:    awssdk.StringValue(out.Policy.Description),
-//		PolicyDocument: pd,
-//		arn:            arn,
-//	}
-//}
-
 // Create attaches the referenced policy on referenced target type and returns the target ARN
 func (p *PolicyInstance) Create(svc iamiface.IAMAPI) error {
 	var newarn awsarn.ARN
-	out, err := createPolicy(svc, p.Name, p.Description, p.PolicyDocument)
+	out, err := createPolicy(svc, p.Name, p.Description, p.Path, p.PolicyDocument, p.Tags)
 	if err != nil {
 		return err
 	}
@@ -175,19 +196,116 @@ func (p *PolicyInstance) Create(svc iamiface.IAMAPI) error {
 }
 
 func (p *PolicyInstance) Read(svc iamiface.IAMAPI) error {
-	panic("Implement me")
+	if !p.IsCreated(svc) {
+		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
+	}
+
+	polout, err := getPolicy(svc, p.arn)
+	if err != nil {
+		return err
+	}
+
+	verout, err := getPolicyVersion(svc, *polout)
+	if err != nil {
+		return err
+	}
+
+	docJson, err := url.QueryUnescape(awssdk.StringValue(verout.PolicyVersion.Document))
+	if err != nil {
+		return err
+	}
+	var pd PolicyDocument
+	if err := json.Unmarshal([]byte(docJson), &pd); err != nil {
+		return err
+	}
+
+	newarn, err := awsarn.Parse(awssdk.StringValue(polout.Policy.Arn))
+	if err != nil {
+		return err
+	}
+
+	p.arn = newarn
+	p.Name = awssdk.StringValue(polout.Policy.PolicyName)
+	p.Description = awssdk.StringValue(polout.Policy.Description)
+	p.PolicyDocument = pd
+	p.Path = awssdk.StringValue(polout.Policy.Path)
+	p.Tags = fromIAMTags(polout.Policy.Tags)
+	p.defaultVersionId = awssdk.StringValue(polout.Policy.DefaultVersionId)
+
+	return nil
+}
+
+// Refresh re-reads the Policy from AWS and reports whether anything changed relative to the
+// previously known state, so callers driving controllers can detect drift without diffing
+// manually.
+func (p *PolicyInstance) Refresh(svc iamiface.IAMAPI) (bool, error) {
+	before := *p
+	if err := p.Read(svc); err != nil {
+		return false, err
+	}
+	return !reflect.DeepEqual(before, *p), nil
 }
 
-// Update for PolicyInstance creates a new Policy version an sets it as active; then returns the arn
+// Update for PolicyInstance creates a new Policy version and sets it as active. If
+// p.defaultVersionId was populated by a prior Read or Update and the policy's live default version
+// no longer matches it, Update refuses to proceed with a ConcurrentModificationError rather than
+// silently overwriting an out-of-band edit. The policy's tags are then reconciled with p.Tags. If
+// the incoming PolicyDocument is semantically Equivalent to the current default version, no new
+// version is created, avoiding needless version churn.
+// Otherwise, before creating the new version, it prunes the oldest non-default version if the
+// policy is already at AWS's version limit.
 func (p *PolicyInstance) Update(svc iamiface.IAMAPI) error {
 	if !p.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
 	}
 
-	_, err := updatePolicy(svc, p.arn, p.PolicyDocument)
+	polout, err := getPolicy(svc, p.arn)
+	if err != nil {
+		return err
+	}
+	liveVersionId := awssdk.StringValue(polout.Policy.DefaultVersionId)
+	if p.defaultVersionId != "" && liveVersionId != p.defaultVersionId {
+		return NewConcurrentModificationError(fmt.Sprintf(
+			"Policy '%s' was modified out-of-band: expected default version '%s', found '%s'",
+			p.Name, p.defaultVersionId, liveVersionId))
+	}
+
+	verout, err := getPolicyVersion(svc, *polout)
+	if err != nil {
+		return err
+	}
+	docJson, err := url.QueryUnescape(awssdk.StringValue(verout.PolicyVersion.Document))
+	if err != nil {
+		return err
+	}
+	var currentDoc PolicyDocument
+	if err := json.Unmarshal([]byte(docJson), &currentDoc); err != nil {
+		return err
+	}
+
+	put, remove := diffTags(fromIAMTags(polout.Policy.Tags), p.Tags)
+	if err := tagPolicy(svc, p.arn, put); err != nil {
+		return err
+	}
+	if err := untagPolicy(svc, p.arn, remove); err != nil {
+		return err
+	}
+
+	if currentDoc.Equivalent(p.PolicyDocument) {
+		p.defaultVersionId = liveVersionId
+		return nil
+	}
+
+	if err := pruneOldestPolicyVersion(svc, p.arn); err != nil {
+		return err
+	}
+
+	out, err := updatePolicy(svc, p.arn, p.PolicyDocument)
 	if err != nil {
 		return err
 	}
+	p.defaultVersionId = awssdk.StringValue(out.PolicyVersion.VersionId)
+
 	return nil
 }
 
@@ -0,0 +1,202 @@
+package iam
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Effect is the Allow/Deny effect of a policy Statement.
+type Effect string
+
+const (
+	Allow Effect = "Allow"
+	Deny  Effect = "Deny"
+)
+
+// PolicyDocument represents an AWS IAM policy document.
+type PolicyDocument struct {
+	Version   string      `json:"Version"`
+	Id        string      `json:"Id,omitempty"`
+	Statement []Statement `json:"Statement"`
+}
+
+// Statement is a single statement within a PolicyDocument. Action, Resource and Principal accept
+// either the single-value or array form AWS itself accepts, so callers unmarshaling existing
+// policies don't need to normalize them up front.
+type Statement struct {
+	Sid       string                            `json:"Sid,omitempty"`
+	Effect    Effect                            `json:"Effect"`
+	Principal interface{}                       `json:"Principal,omitempty"`
+	Action    interface{}                       `json:"Action,omitempty"`
+	Resource  interface{}                       `json:"Resource,omitempty"`
+	Condition map[string]map[string]interface{} `json:"Condition,omitempty"`
+}
+
+// NewPolicyDocument returns an empty PolicyDocument using the current IAM policy language version.
+func NewPolicyDocument() *PolicyDocument {
+	return &PolicyDocument{Version: "2012-10-17"}
+}
+
+// StatementBuilder builds a single Statement in place on its parent PolicyDocument.
+type StatementBuilder struct {
+	doc *PolicyDocument
+	idx int
+}
+
+// NewStatement appends a new, empty Statement to the document and returns a builder for it.
+func (pd *PolicyDocument) NewStatement() *StatementBuilder {
+	pd.Statement = append(pd.Statement, Statement{})
+	return &StatementBuilder{doc: pd, idx: len(pd.Statement) - 1}
+}
+
+func (b *StatementBuilder) stmt() *Statement {
+	return &b.doc.Statement[b.idx]
+}
+
+// Allow sets the statement's effect to Allow.
+func (b *StatementBuilder) Allow() *StatementBuilder {
+	b.stmt().Effect = Allow
+	return b
+}
+
+// Deny sets the statement's effect to Deny.
+func (b *StatementBuilder) Deny() *StatementBuilder {
+	b.stmt().Effect = Deny
+	return b
+}
+
+// Sid sets the statement's Sid.
+func (b *StatementBuilder) Sid(sid string) *StatementBuilder {
+	b.stmt().Sid = sid
+	return b
+}
+
+// Actions sets the statement's Action list.
+func (b *StatementBuilder) Actions(actions ...string) *StatementBuilder {
+	b.stmt().Action = actions
+	return b
+}
+
+// Resources sets the statement's Resource list.
+func (b *StatementBuilder) Resources(resources ...string) *StatementBuilder {
+	b.stmt().Resource = resources
+	return b
+}
+
+// Principal sets the statement's Principal, e.g. "*" or map[string]interface{}{"AWS": "..."}.
+func (b *StatementBuilder) Principal(principal interface{}) *StatementBuilder {
+	b.stmt().Principal = principal
+	return b
+}
+
+// Condition adds a condition operator/key/values triple to the statement, merging into any
+// existing conditions under the same operator.
+func (b *StatementBuilder) Condition(operator, key string, values ...string) *StatementBuilder {
+	s := b.stmt()
+	if s.Condition == nil {
+		s.Condition = map[string]map[string]interface{}{}
+	}
+	if s.Condition[operator] == nil {
+		s.Condition[operator] = map[string]interface{}{}
+	}
+	s.Condition[operator][key] = values
+	return b
+}
+
+// Equivalent reports whether pd and other describe the same effective policy, ignoring cosmetic
+// differences AWS itself treats as equal: single-element vs array forms of Action/Resource, the
+// "*" vs {"AWS":"*"} Principal shapes, and unordered Condition/Statement ordering.
+func (pd PolicyDocument) Equivalent(other PolicyDocument) bool {
+	return reflect.DeepEqual(normalizePolicyDocument(pd), normalizePolicyDocument(other))
+}
+
+type normalizedStatement struct {
+	Sid       string
+	Effect    Effect
+	Principal string
+	Action    []string
+	Resource  []string
+	Condition map[string]map[string][]string
+}
+
+func normalizePolicyDocument(pd PolicyDocument) []normalizedStatement {
+	out := make([]normalizedStatement, 0, len(pd.Statement))
+	for _, s := range pd.Statement {
+		out = append(out, normalizedStatement{
+			Sid:       s.Sid,
+			Effect:    s.Effect,
+			Principal: normalizePrincipal(s.Principal),
+			Action:    normalizeStringOrSlice(s.Action),
+			Resource:  normalizeStringOrSlice(s.Resource),
+			Condition: normalizeCondition(s.Condition),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return fmt.Sprintf("%+v", out[i]) < fmt.Sprintf("%+v", out[j])
+	})
+	return out
+}
+
+// normalizeStringOrSlice collapses the single-string and string-slice forms IAM accepts for
+// Action/Resource into a sorted []string.
+func normalizeStringOrSlice(v interface{}) []string {
+	var out []string
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		out = []string{t}
+	case []string:
+		out = append(out, t...)
+	case []interface{}:
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// normalizeCondition collapses each condition value's single-string and string-slice forms into a
+// sorted []string, so a builder-constructed Condition (always []string) compares equal to the same
+// condition read back from AWS via json.Unmarshal ([]interface{}, or string for a bare value).
+func normalizeCondition(c map[string]map[string]interface{}) map[string]map[string][]string {
+	if c == nil {
+		return nil
+	}
+	out := make(map[string]map[string][]string, len(c))
+	for operator, keys := range c {
+		normKeys := make(map[string][]string, len(keys))
+		for key, v := range keys {
+			normKeys[key] = normalizeStringOrSlice(v)
+		}
+		out[operator] = normKeys
+	}
+	return out
+}
+
+// normalizePrincipal canonicalizes the "*" and {"AWS":"*"} Principal shapes, which IAM treats as
+// equivalent, to the same string; anything else is normalized via its canonical JSON encoding.
+func normalizePrincipal(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case map[string]interface{}:
+		if aws, ok := t["AWS"]; ok {
+			if s, ok := aws.(string); ok && s == "*" {
+				return "*"
+			}
+		}
+		b, _ := json.Marshal(t)
+		return string(b)
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
@@ -0,0 +1,53 @@
+package iam
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestPolicyDocument_Equivalent_ConditionRoundTrip ensures a Condition added via StatementBuilder
+// still compares equal to itself after a JSON round-trip, where []string values become
+// []interface{}.
+func TestPolicyDocument_Equivalent_ConditionRoundTrip(t *testing.T) {
+	pd := NewPolicyDocument()
+	pd.NewStatement().Allow().Actions("s3:GetObject").Resources("*").Condition("StringEquals", "aws:PrincipalTag/team", "Admin")
+
+	b, err := json.Marshal(pd)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var pd2 PolicyDocument
+	if err := json.Unmarshal(b, &pd2); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if !pd.Equivalent(pd2) {
+		t.Fatalf("expected round-tripped document to be equivalent, got:\n%+v\nvs\n%+v", pd, pd2)
+	}
+}
+
+// TestPolicyDocument_Equivalent_ConditionSingleValue ensures a single condition value normalizes
+// the same whether expressed as a bare string or a one-element []string.
+func TestPolicyDocument_Equivalent_ConditionSingleValue(t *testing.T) {
+	pd := NewPolicyDocument()
+	pd.NewStatement().Allow().Actions("s3:GetObject").Resources("*").Condition("StringEquals", "aws:PrincipalTag/team", "Admin")
+
+	var other PolicyDocument
+	raw := `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Effect": "Allow",
+			"Action": "s3:GetObject",
+			"Resource": "*",
+			"Condition": {"StringEquals": {"aws:PrincipalTag/team": "Admin"}}
+		}]
+	}`
+	if err := json.Unmarshal([]byte(raw), &other); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if !pd.Equivalent(other) {
+		t.Fatalf("expected single-value condition to be equivalent to []string form, got:\n%+v\nvs\n%+v", pd, other)
+	}
+}
@@ -0,0 +1,60 @@
+package iam
+
+import (
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+)
+
+// maxPolicyVersions is the AWS-enforced limit on the number of versions a managed policy may
+// retain at once. Creating a new version beyond this limit fails until an old one is pruned.
+const maxPolicyVersions = 5
+
+// ConcurrentModificationError is returned by PolicyInstance.Update when the policy's default
+// version has changed since it was last Read or Updated, indicating an out-of-band edit.
+type ConcurrentModificationError struct {
+	msg string
+}
+
+func NewConcurrentModificationError(msg string) ConcurrentModificationError {
+	return ConcurrentModificationError{msg: msg}
+}
+
+func (e ConcurrentModificationError) Error() string {
+	return e.msg
+}
+
+// pruneOldestPolicyVersion deletes the oldest non-default version of the given policy if it is
+// at the AWS version limit, making room for CreatePolicyVersion to succeed.
+func pruneOldestPolicyVersion(svc iamiface.IAMAPI, arn awsarn.ARN) error {
+	listOut, err := svc.ListPolicyVersions(&iam.ListPolicyVersionsInput{
+		PolicyArn: awssdk.String(arn.String()),
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(listOut.Versions) < maxPolicyVersions {
+		return nil
+	}
+
+	var oldest *iam.PolicyVersion
+	for _, version := range listOut.Versions {
+		if awssdk.BoolValue(version.IsDefaultVersion) {
+			continue
+		}
+		if oldest == nil || version.CreateDate.Before(*oldest.CreateDate) {
+			oldest = version
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+
+	_, err = svc.DeletePolicyVersion(&iam.DeletePolicyVersionInput{
+		PolicyArn: awssdk.String(arn.String()),
+		VersionId: oldest.VersionId,
+	})
+	return err
+}
@@ -0,0 +1,87 @@
+package iam
+
+import (
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+)
+
+// fakeVersionsIAMAPI fakes just the ListPolicyVersions/DeletePolicyVersion calls
+// pruneOldestPolicyVersion makes, embedding iamiface.IAMAPI so it satisfies the interface without
+// implementing every method.
+type fakeVersionsIAMAPI struct {
+	iamiface.IAMAPI
+
+	versions []*iam.PolicyVersion
+	deleted  string
+}
+
+func (f *fakeVersionsIAMAPI) ListPolicyVersions(in *iam.ListPolicyVersionsInput) (*iam.ListPolicyVersionsOutput, error) {
+	return &iam.ListPolicyVersionsOutput{Versions: f.versions}, nil
+}
+
+func (f *fakeVersionsIAMAPI) DeletePolicyVersion(in *iam.DeletePolicyVersionInput) (*iam.DeletePolicyVersionOutput, error) {
+	f.deleted = awssdk.StringValue(in.VersionId)
+	return &iam.DeletePolicyVersionOutput{}, nil
+}
+
+func versionAt(id string, isDefault bool, t time.Time) *iam.PolicyVersion {
+	return &iam.PolicyVersion{
+		VersionId:        awssdk.String(id),
+		IsDefaultVersion: awssdk.Bool(isDefault),
+		CreateDate:       awssdk.Time(t),
+	}
+}
+
+func TestPruneOldestPolicyVersion_SkipsDefaultPicksOldest(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := &fakeVersionsIAMAPI{
+		versions: []*iam.PolicyVersion{
+			versionAt("v1", false, base),
+			versionAt("v2", false, base.Add(1*time.Hour)),
+			versionAt("v3", true, base.Add(2*time.Hour)),
+			versionAt("v4", false, base.Add(3*time.Hour)),
+			versionAt("v5", false, base.Add(4*time.Hour)),
+		},
+	}
+
+	arn, err := awsarn.Parse("arn:aws:iam::123456789012:policy/test")
+	if err != nil {
+		t.Fatalf("parse arn: %v", err)
+	}
+
+	if err := pruneOldestPolicyVersion(fake, arn); err != nil {
+		t.Fatalf("pruneOldestPolicyVersion: %v", err)
+	}
+
+	if fake.deleted != "v1" {
+		t.Fatalf("expected oldest non-default version v1 to be deleted, got %q", fake.deleted)
+	}
+}
+
+func TestPruneOldestPolicyVersion_NoopBelowLimit(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := &fakeVersionsIAMAPI{
+		versions: []*iam.PolicyVersion{
+			versionAt("v1", false, base),
+			versionAt("v2", true, base.Add(1*time.Hour)),
+		},
+	}
+
+	arn, err := awsarn.Parse("arn:aws:iam::123456789012:policy/test")
+	if err != nil {
+		t.Fatalf("parse arn: %v", err)
+	}
+
+	if err := pruneOldestPolicyVersion(fake, arn); err != nil {
+		t.Fatalf("pruneOldestPolicyVersion: %v", err)
+	}
+
+	if fake.deleted != "" {
+		t.Fatalf("expected no deletion below the version limit, got %q deleted", fake.deleted)
+	}
+}
@@ -3,6 +3,8 @@ package iam
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"reflect"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
@@ -13,19 +15,28 @@ import (
 	"github.com/redradrat/cloud-objects/aws"
 )
 
-func createRole(svc iamiface.IAMAPI, rn string, roleDesc string, sessionDuration int64, pd PolicyDocument) (*awsiam.CreateRoleOutput, error) {
+func createRole(svc iamiface.IAMAPI, rn string, roleDesc, path string, sessionDuration int64, pd PolicyDocument, permissionsBoundary awsarn.ARN, tags map[string]string) (*awsiam.CreateRoleOutput, error) {
 
 	b, err := json.Marshal(&pd)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := svc.CreateRole(&awsiam.CreateRoleInput{
+	input := &awsiam.CreateRoleInput{
 		AssumeRolePolicyDocument: awssdk.String(string(b)),
 		Description:              awssdk.String(roleDesc),
 		MaxSessionDuration:       awssdk.Int64(sessionDuration),
 		RoleName:                 awssdk.String(rn),
-	})
+		Tags:                     toIAMTags(tags),
+	}
+	if path != "" {
+		input.Path = awssdk.String(path)
+	}
+	if permissionsBoundary.String() != (awsarn.ARN{}).String() {
+		input.PermissionsBoundary = awssdk.String(permissionsBoundary.String())
+	}
+
+	result, err := svc.CreateRole(input)
 	if err != nil {
 		return nil, err
 	}
@@ -33,6 +44,43 @@ func createRole(svc iamiface.IAMAPI, rn string, roleDesc string, sessionDuration
 	return result, nil
 }
 
+func putRolePermissionsBoundary(svc iamiface.IAMAPI, roleName string, permissionsBoundary awsarn.ARN) error {
+	_, err := svc.PutRolePermissionsBoundary(&awsiam.PutRolePermissionsBoundaryInput{
+		RoleName:            awssdk.String(roleName),
+		PermissionsBoundary: awssdk.String(permissionsBoundary.String()),
+	})
+	return err
+}
+
+func deleteRolePermissionsBoundary(svc iamiface.IAMAPI, roleName string) error {
+	_, err := svc.DeleteRolePermissionsBoundary(&awsiam.DeleteRolePermissionsBoundaryInput{
+		RoleName: awssdk.String(roleName),
+	})
+	return err
+}
+
+func tagRole(svc iamiface.IAMAPI, roleName string, tags map[string]string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := svc.TagRole(&awsiam.TagRoleInput{
+		RoleName: awssdk.String(roleName),
+		Tags:     toIAMTags(tags),
+	})
+	return err
+}
+
+func untagRole(svc iamiface.IAMAPI, roleName string, tagKeys []string) error {
+	if len(tagKeys) == 0 {
+		return nil
+	}
+	_, err := svc.UntagRole(&awsiam.UntagRoleInput{
+		RoleName: awssdk.String(roleName),
+		TagKeys:  awssdk.StringSlice(tagKeys),
+	})
+	return err
+}
+
 func updateRole(svc iamiface.IAMAPI, roleArn awsarn.ARN, roleDesc string, pd PolicyDocument) (*awsiam.UpdateRoleOutput, error) {
 
 	result, err := svc.UpdateRole(&awsiam.UpdateRoleInput{
@@ -104,71 +152,81 @@ type RoleInstance struct {
 	PolicyDocument     PolicyDocument
 	MaxSessionDuration int64
 	arn                awsarn.ARN
+
+	// AttachedManagedPolicies reflects the managed policies currently attached to the role, as
+	// observed during the last Read. It is populated for reconciliation purposes and is not
+	// itself applied by Create/Update.
+	AttachedManagedPolicies []awsarn.ARN
+
+	// ManagedPolicies and InlinePolicies declare the desired policies on the role. They are only
+	// enforced as an exhaustive set when Exclusive is true; otherwise they are ignored, and
+	// policies must be attached separately via PolicyInstance.AttachToRole or PutRolePolicy.
+	ManagedPolicies []awsarn.ARN
+	InlinePolicies  map[string]PolicyDocument
+
+	// Exclusive makes Create/Update authoritative over the role's policies: any managed policy
+	// attachment or inline policy not present in ManagedPolicies/InlinePolicies is detached or
+	// deleted as drift.
+	Exclusive bool
+
+	// DryRun, when set alongside Exclusive, makes Create/Update compute and return the
+	// RolePolicyDiff they would otherwise apply, without calling any of the managed or inline
+	// policy attach/detach/put/delete APIs. The role itself, its permissions boundary and its tags
+	// are still created or updated.
+	DryRun bool
+
+	// Path is the IAM path the role is created under. Defaults to "/" when empty.
+	Path string
+	// PermissionsBoundary is the ARN of the managed policy used as the role's permissions
+	// boundary. Leave unset for no boundary.
+	PermissionsBoundary awsarn.ARN
+	// Tags are the IAM tags applied to the role.
+	Tags map[string]string
 }
 
-func NewRoleInstance(name string, description string, sessionDuration int64, poldoc PolicyDocument) *RoleInstance {
-	return &RoleInstance{
+func NewRoleInstance(name string, description string, sessionDuration int64, poldoc PolicyDocument, opts ...RoleInstanceOption) *RoleInstance {
+	r := &RoleInstance{
 		Name:               name,
 		Description:        description,
 		PolicyDocument:     poldoc,
 		MaxSessionDuration: sessionDuration,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-func NewExistingRoleInstance(name string, description string, sessionDuration int64, poldoc PolicyDocument, arn awsarn.ARN) *RoleInstance {
-	return &RoleInstance{
+func NewExistingRoleInstance(name string, description string, sessionDuration int64, poldoc PolicyDocument, arn awsarn.ARN, opts ...RoleInstanceOption) *RoleInstance {
+	r := &RoleInstance{
 		Name:               name,
 		Description:        description,
 		PolicyDocument:     poldoc,
 		MaxSessionDuration: sessionDuration,
 		arn:                arn,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-// An old fetch implementation; abandoned due to sync problems
-//func NewExistingRoleInstance(svc iamiface.IAMAPI, arn awsarn.ARN) (*RoleInstance, error) {
-//	var ri *RoleInstance
-//	emptyarn := awsarn.ARN{}.String()
-//	if arn.String() == emptyarn {
-//		return ri, fmt.Errorf("given ARN is empty")
-//	}
-//
-//	out, err := getRole(svc, arn)
-//	if err != nil {
-//		return ri, err
-//	}
-//
-//	var pd PolicyDocument
-//	policyJson, err := url.QueryUnescape(awssdk.StringValue(out.Role.AssumeRolePolicyDocument))
-//	if err != nil {
-//		return ri, err
-//	}
-//	if err = json.Unmarshal([]byte(policyJson), &pd); err != nil {
-//		return ri, err
-//	}
-//	ri = &RoleInstance{
-//		Name:           awssdk.StringValue(out.Role.RoleName),
-//		Description:    awssdk.StringValue(out.Role.Description),
-//		PolicyDocument: pd,
-//		arn:            arn,
-//	}
-//
-//	return ri, nil
-//}
-
-// Reconcile creates or updates an AWS Role
-func (r *RoleInstance) Create(svc iamiface.IAMAPI) error {
+// Create creates the AWS Role. If Exclusive is set, it also reconciles the role's managed and
+// inline policies to match ManagedPolicies/InlinePolicies exactly; if DryRun is also set, the
+// resulting RolePolicyDiff is returned instead of being applied.
+func (r *RoleInstance) Create(svc iamiface.IAMAPI) (RolePolicyDiff, error) {
 	var newarn awsarn.ARN
-	out, err := createRole(svc, r.Name, r.Description, r.MaxSessionDuration, r.PolicyDocument)
+	out, err := createRole(svc, r.Name, r.Description, r.Path, r.MaxSessionDuration, r.PolicyDocument, r.PermissionsBoundary, r.Tags)
 	if err != nil {
-		return err
+		return RolePolicyDiff{}, err
 	}
 	newarn, err = awsarn.Parse(awssdk.StringValue(out.Role.Arn))
 	if err != nil {
-		return err
+		return RolePolicyDiff{}, err
 	}
 	r.arn = newarn
-	return nil
+
+	return r.reconcileExclusivePolicies(svc)
 }
 
 func (r *RoleInstance) Read(svc iamiface.IAMAPI) error {
@@ -184,19 +242,86 @@ func (r *RoleInstance) Read(svc iamiface.IAMAPI) error {
 	r.Description = *roleout.Role.Description
 	r.MaxSessionDuration = *roleout.Role.MaxSessionDuration
 	r.Name = *roleout.Role.RoleName
+
+	policyJson, err := url.QueryUnescape(awssdk.StringValue(roleout.Role.AssumeRolePolicyDocument))
+	if err != nil {
+		return err
+	}
+	var pd PolicyDocument
+	if err := json.Unmarshal([]byte(policyJson), &pd); err != nil {
+		return err
+	}
+	r.PolicyDocument = pd
+	r.Path = awssdk.StringValue(roleout.Role.Path)
+	r.Tags = fromIAMTags(roleout.Role.Tags)
+	if roleout.Role.PermissionsBoundary != nil {
+		boundaryArn, err := awsarn.Parse(awssdk.StringValue(roleout.Role.PermissionsBoundary.PermissionsBoundaryArn))
+		if err != nil {
+			return err
+		}
+		r.PermissionsBoundary = boundaryArn
+	} else {
+		r.PermissionsBoundary = awsarn.ARN{}
+	}
+
+	attached, err := listAttachedRolePolicies(svc, r.Name)
+	if err != nil {
+		return err
+	}
+	r.AttachedManagedPolicies = attached
+
 	return nil
 }
 
-func (r *RoleInstance) Update(svc iamiface.IAMAPI) error {
+// Refresh re-reads the Role from AWS and reports whether anything changed relative to the
+// previously known state, so callers driving controllers can detect drift without diffing
+// manually.
+func (r *RoleInstance) Refresh(svc iamiface.IAMAPI) (bool, error) {
+	before := *r
+	if err := r.Read(svc); err != nil {
+		return false, err
+	}
+	return !reflect.DeepEqual(before, *r), nil
+}
+
+// Update updates the AWS Role. If Exclusive is set, it also reconciles the role's managed and
+// inline policies to match ManagedPolicies/InlinePolicies exactly; if DryRun is also set, the
+// resulting RolePolicyDiff is returned instead of being applied.
+func (r *RoleInstance) Update(svc iamiface.IAMAPI) (RolePolicyDiff, error) {
 	if !r.IsCreated(svc) {
-		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Role '%s' not yet created", r.Name))
+		return RolePolicyDiff{}, aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Role '%s' not yet created", r.Name))
 	}
 
 	_, err := updateRole(svc, r.arn, r.Description, r.PolicyDocument)
 	if err != nil {
-		return err
+		return RolePolicyDiff{}, err
 	}
-	return nil
+
+	roleout, err := getRoleByName(r.Name, svc)
+	if err != nil {
+		return RolePolicyDiff{}, err
+	}
+
+	emptyArn := awsarn.ARN{}
+	if r.PermissionsBoundary.String() != emptyArn.String() {
+		if err := putRolePermissionsBoundary(svc, r.Name, r.PermissionsBoundary); err != nil {
+			return RolePolicyDiff{}, err
+		}
+	} else if roleout.Role.PermissionsBoundary != nil {
+		if err := deleteRolePermissionsBoundary(svc, r.Name); err != nil {
+			return RolePolicyDiff{}, err
+		}
+	}
+
+	put, remove := diffTags(fromIAMTags(roleout.Role.Tags), r.Tags)
+	if err := tagRole(svc, r.Name, put); err != nil {
+		return RolePolicyDiff{}, err
+	}
+	if err := untagRole(svc, r.Name, remove); err != nil {
+		return RolePolicyDiff{}, err
+	}
+
+	return r.reconcileExclusivePolicies(svc)
 }
 
 func (r *RoleInstance) Delete(svc iamiface.IAMAPI) error {
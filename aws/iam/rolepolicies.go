@@ -0,0 +1,205 @@
+package iam
+
+import (
+	"encoding/json"
+	"net/url"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+)
+
+func listRolePolicyNames(svc iamiface.IAMAPI, roleName string) ([]string, error) {
+	out, err := svc.ListRolePolicies(&iam.ListRolePoliciesInput{
+		RoleName: awssdk.String(roleName),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return awssdk.StringValueSlice(out.PolicyNames), nil
+}
+
+func getRolePolicy(svc iamiface.IAMAPI, roleName, policyName string) (PolicyDocument, error) {
+	var pd PolicyDocument
+	out, err := svc.GetRolePolicy(&iam.GetRolePolicyInput{
+		RoleName:   awssdk.String(roleName),
+		PolicyName: awssdk.String(policyName),
+	})
+	if err != nil {
+		return pd, err
+	}
+	docJson, err := url.QueryUnescape(awssdk.StringValue(out.PolicyDocument))
+	if err != nil {
+		return pd, err
+	}
+	if err := json.Unmarshal([]byte(docJson), &pd); err != nil {
+		return pd, err
+	}
+	return pd, nil
+}
+
+func putRolePolicy(svc iamiface.IAMAPI, roleName, policyName string, pd PolicyDocument) error {
+	b, err := json.Marshal(&pd)
+	if err != nil {
+		return err
+	}
+	_, err = svc.PutRolePolicy(&iam.PutRolePolicyInput{
+		RoleName:       awssdk.String(roleName),
+		PolicyName:     awssdk.String(policyName),
+		PolicyDocument: awssdk.String(string(b)),
+	})
+	return err
+}
+
+func deleteRolePolicy(svc iamiface.IAMAPI, roleName, policyName string) error {
+	_, err := svc.DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+		RoleName:   awssdk.String(roleName),
+		PolicyName: awssdk.String(policyName),
+	})
+	return err
+}
+
+// RolePolicyDiff describes the reconciliation actions needed to bring a role's attached managed
+// policies and inline policies in line with a desired, authoritative set.
+type RolePolicyDiff struct {
+	AttachManagedPolicies []awsarn.ARN
+	DetachManagedPolicies []awsarn.ARN
+	PutInlinePolicies     map[string]PolicyDocument
+	DeleteInlinePolicies  []string
+}
+
+// Empty reports whether the diff contains no reconciliation actions.
+func (d RolePolicyDiff) Empty() bool {
+	return len(d.AttachManagedPolicies) == 0 && len(d.DetachManagedPolicies) == 0 &&
+		len(d.PutInlinePolicies) == 0 && len(d.DeleteInlinePolicies) == 0
+}
+
+func diffManagedPolicies(current, desired []awsarn.ARN) (attach []awsarn.ARN, detach []awsarn.ARN) {
+	desiredSet := make(map[string]awsarn.ARN, len(desired))
+	for _, arn := range desired {
+		desiredSet[arn.String()] = arn
+	}
+	currentSet := make(map[string]bool, len(current))
+	for _, arn := range current {
+		currentSet[arn.String()] = true
+		if _, ok := desiredSet[arn.String()]; !ok {
+			detach = append(detach, arn)
+		}
+	}
+	for s, arn := range desiredSet {
+		if !currentSet[s] {
+			attach = append(attach, arn)
+		}
+	}
+	return
+}
+
+// diffInlinePolicies determines which of the desired inline policies actually need a PutRolePolicy
+// call, skipping any whose live document is already Equivalent to what's desired, and which
+// currently-attached inline policies are no longer desired and need removing.
+func diffInlinePolicies(svc iamiface.IAMAPI, roleName string, currentNames []string, desired map[string]PolicyDocument) (put map[string]PolicyDocument, remove []string, err error) {
+	put = make(map[string]PolicyDocument)
+	desiredNames := make(map[string]bool, len(desired))
+	currentSet := make(map[string]bool, len(currentNames))
+	for _, name := range currentNames {
+		currentSet[name] = true
+	}
+	for name, pd := range desired {
+		desiredNames[name] = true
+		if currentSet[name] {
+			current, getErr := getRolePolicy(svc, roleName, name)
+			if getErr != nil {
+				return nil, nil, getErr
+			}
+			if current.Equivalent(pd) {
+				continue
+			}
+		}
+		put[name] = pd
+	}
+	for _, name := range currentNames {
+		if !desiredNames[name] {
+			remove = append(remove, name)
+		}
+	}
+	return
+}
+
+// policyDiff computes the RolePolicyDiff needed to make the role's attached and inline policies
+// match r.ManagedPolicies and r.InlinePolicies exactly.
+func (r *RoleInstance) policyDiff(svc iamiface.IAMAPI) (RolePolicyDiff, error) {
+	attached, err := listAttachedRolePolicies(svc, r.Name)
+	if err != nil {
+		return RolePolicyDiff{}, err
+	}
+	inlineNames, err := listRolePolicyNames(svc, r.Name)
+	if err != nil {
+		return RolePolicyDiff{}, err
+	}
+
+	attach, detach := diffManagedPolicies(attached, r.ManagedPolicies)
+	put, remove, err := diffInlinePolicies(svc, r.Name, inlineNames, r.InlinePolicies)
+	if err != nil {
+		return RolePolicyDiff{}, err
+	}
+
+	return RolePolicyDiff{
+		AttachManagedPolicies: attach,
+		DetachManagedPolicies: detach,
+		PutInlinePolicies:     put,
+		DeleteInlinePolicies:  remove,
+	}, nil
+}
+
+// PolicyDiff returns the reconciliation actions that Update would take to enforce r.ManagedPolicies
+// and r.InlinePolicies, without applying them. It is a no-op (empty diff, nil error) unless
+// r.Exclusive is set.
+func (r *RoleInstance) PolicyDiff(svc iamiface.IAMAPI) (RolePolicyDiff, error) {
+	if !r.Exclusive {
+		return RolePolicyDiff{}, nil
+	}
+	return r.policyDiff(svc)
+}
+
+// reconcileExclusivePolicies enforces r.ManagedPolicies and r.InlinePolicies as the exhaustive set
+// of policies on the role, detaching/deleting anything not listed, and returns the RolePolicyDiff
+// it applied. It is a no-op unless r.Exclusive is set. If r.DryRun is also set, the diff is
+// computed and returned but none of the attach/detach/put/delete calls are made.
+func (r *RoleInstance) reconcileExclusivePolicies(svc iamiface.IAMAPI) (RolePolicyDiff, error) {
+	if !r.Exclusive {
+		return RolePolicyDiff{}, nil
+	}
+
+	diff, err := r.policyDiff(svc)
+	if err != nil {
+		return RolePolicyDiff{}, err
+	}
+
+	if r.DryRun {
+		return diff, nil
+	}
+
+	for _, arn := range diff.DetachManagedPolicies {
+		if err := detachRolePolicy(svc, r.Name, arn); err != nil {
+			return RolePolicyDiff{}, err
+		}
+	}
+	for _, arn := range diff.AttachManagedPolicies {
+		if err := attachRolePolicy(svc, r.Name, arn); err != nil {
+			return RolePolicyDiff{}, err
+		}
+	}
+	for _, name := range diff.DeleteInlinePolicies {
+		if err := deleteRolePolicy(svc, r.Name, name); err != nil {
+			return RolePolicyDiff{}, err
+		}
+	}
+	for name, pd := range diff.PutInlinePolicies {
+		if err := putRolePolicy(svc, r.Name, name, pd); err != nil {
+			return RolePolicyDiff{}, err
+		}
+	}
+
+	return diff, nil
+}